@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"sync/atomic"
+
+	fsnotify "github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	configReloadsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "config_reloads_total",
+		Help:      "Total -config-file reload attempts by result",
+	}, []string{"result"})
+	configLastReloadSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "config_last_reload_success_timestamp_seconds",
+		Help:      "Unix timestamp of the last successful -config-file reload",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(configReloadsTotal)
+	prometheus.MustRegister(configLastReloadSuccess)
+}
+
+// rule is the runtime form of one watch rule, resolved from either
+// -config-file or the legacy -volume-dir/-webhook-url flags.
+type rule struct {
+	volumeDir   string
+	filePattern string
+	writeToPath string
+	envPrefix   string
+	webhooks    []ruleWebhook
+}
+
+type ruleWebhook struct {
+	url        *url.URL
+	method     string
+	statusCode int
+	retries    int
+}
+
+// fileConfig is the on-disk schema for -config-file, accepted as either
+// YAML or JSON (JSON is valid YAML, so a single decoder handles both).
+type fileConfig struct {
+	Rules []fileRule `yaml:"rules"`
+}
+
+type fileRule struct {
+	VolumeDir   string            `yaml:"volume_dir"`
+	FilePattern string            `yaml:"file_pattern"`
+	WriteToPath string            `yaml:"write_to_path"`
+	EnvPrefix   string            `yaml:"env_prefix"`
+	Webhooks    []fileRuleWebhook `yaml:"webhooks"`
+}
+
+type fileRuleWebhook struct {
+	URL        string         `yaml:"url"`
+	Method     string         `yaml:"method"`
+	StatusCode int            `yaml:"status_code"`
+	Retries    int            `yaml:"retries"`
+	BasicAuth  *fileBasicAuth `yaml:"basic_auth"`
+}
+
+type fileBasicAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+var currentRules atomic.Value
+
+func storeRules(rules []rule) {
+	currentRules.Store(rules)
+}
+
+func loadRules() []rule {
+	rules, _ := currentRules.Load().([]rule)
+	return rules
+}
+
+// rulesFromFlags builds the legacy single-ruleset-per-volume-dir behavior
+// out of the static -volume-dir/-webhook-url flags, for when -config-file
+// is not set.
+func rulesFromFlags() []rule {
+	webhooks := make([]ruleWebhook, 0, len(webhook))
+	for _, h := range webhook {
+		webhooks = append(webhooks, ruleWebhook{
+			url:        h,
+			method:     *webhookMethod,
+			statusCode: *webhookStatusCode,
+			retries:    *webhookRetries,
+		})
+	}
+
+	rules := make([]rule, 0, len(volumeDirs))
+	for _, d := range volumeDirs {
+		rules = append(rules, rule{
+			volumeDir:   d,
+			filePattern: *filePattern,
+			writeToPath: *writeToPattern,
+			envPrefix:   *envPrefix,
+			webhooks:    webhooks,
+		})
+	}
+	return rules
+}
+
+func loadRulesFromFile(path string) ([]rule, error) {
+	cfg, err := parseConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return rulesFromFileConfig(cfg)
+}
+
+func parseConfigFile(path string) (*fileConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %v", path, err)
+	}
+	var cfg fileConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", path, err)
+	}
+	return &cfg, nil
+}
+
+func rulesFromFileConfig(cfg *fileConfig) ([]rule, error) {
+	if len(cfg.Rules) == 0 {
+		return nil, fmt.Errorf("config file defines no rules")
+	}
+
+	rules := make([]rule, 0, len(cfg.Rules))
+	for i, fr := range cfg.Rules {
+		if fr.VolumeDir == "" {
+			return nil, fmt.Errorf("rules[%d]: volume_dir is required", i)
+		}
+		if fr.FilePattern == "" {
+			fr.FilePattern = "*.yml"
+		}
+		if fr.WriteToPath == "" {
+			return nil, fmt.Errorf("rules[%d]: write_to_path is required", i)
+		}
+
+		webhooks := make([]ruleWebhook, 0, len(fr.Webhooks))
+		for j, fw := range fr.Webhooks {
+			u, err := url.Parse(fw.URL)
+			if err != nil {
+				return nil, fmt.Errorf("rules[%d].webhooks[%d]: invalid url: %v", i, j, err)
+			}
+			if fw.BasicAuth != nil {
+				u.User = url.UserPassword(fw.BasicAuth.Username, fw.BasicAuth.Password)
+			}
+			method := fw.Method
+			if method == "" {
+				method = "POST"
+			}
+			statusCode := fw.StatusCode
+			if statusCode == 0 {
+				statusCode = 200
+			}
+			retries := fw.Retries
+			if retries == 0 {
+				retries = 1
+			}
+			webhooks = append(webhooks, ruleWebhook{
+				url:        u,
+				method:     method,
+				statusCode: statusCode,
+				retries:    retries,
+			})
+		}
+
+		rules = append(rules, rule{
+			volumeDir:   fr.VolumeDir,
+			filePattern: fr.FilePattern,
+			writeToPath: fr.WriteToPath,
+			envPrefix:   fr.EnvPrefix,
+			webhooks:    webhooks,
+		})
+	}
+	return rules, nil
+}
+
+// isConfigFileEvent reports whether event is an update to configFile, as
+// opposed to one of the watched ConfigMap volume dirs. A ConfigMap-mounted
+// config file is updated via an atomic ..data symlink swap in its
+// directory (the same pattern isValidEvent matches for volume dirs), not a
+// write to the literal path, so that case is checked first; a direct write
+// to the literal path is also accepted for plain (non-ConfigMap) files.
+func isConfigFileEvent(event fsnotify.Event, configFile string) bool {
+	name := filepath.Clean(event.Name)
+	configFile = filepath.Clean(configFile)
+
+	if filepath.Dir(name) == filepath.Dir(configFile) && filepath.Base(name) == "..data" {
+		return event.Op&fsnotify.Create == fsnotify.Create
+	}
+
+	if name != configFile {
+		return false
+	}
+	return event.Op&(fsnotify.Write|fsnotify.Create) != 0
+}
+
+// reloadConfigFile re-parses configFile and, on success, atomically swaps
+// in the new ruleset and starts watching any newly added volume dirs. On
+// failure the previously loaded ruleset is left in effect.
+func reloadConfigFile(watcher *fsnotify.Watcher, configFile string) {
+	newRules, err := loadRulesFromFile(configFile)
+	if err != nil {
+		configReloadsTotal.WithLabelValues("failure").Inc()
+		logger.Error("error reloading config file, keeping previous ruleset", "file", configFile, "reason", err)
+		return
+	}
+
+	oldRules := loadRules()
+	watched := make(map[string]bool, len(oldRules))
+	for _, r := range oldRules {
+		watched[r.volumeDir] = true
+	}
+	for _, r := range newRules {
+		if watched[r.volumeDir] {
+			continue
+		}
+		if err := watcher.Add(r.volumeDir); err != nil {
+			configReloadsTotal.WithLabelValues("failure").Inc()
+			logger.Error("error watching new volume-dir from config file, keeping previous ruleset", "file", configFile, "volume_dir", r.volumeDir, "reason", err)
+			return
+		}
+	}
+
+	storeRules(newRules)
+	configReloadsTotal.WithLabelValues("success").Inc()
+	configLastReloadSuccess.SetToCurrentTime()
+	logger.Info("reloaded config file", "file", configFile)
+}
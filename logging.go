@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	logLevel  = flag.String("log.level", "info", "log level to use (debug, info, warn, error)")
+	logFormat = flag.String("log.format", "logfmt", "log format to use (logfmt, json)")
+
+	logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+)
+
+// setupLogger builds the package-wide logger from -log.level/-log.format,
+// wrapping the chosen handler in a dedupingHandler so that repeated
+// identical lines (e.g. "no environment variable with prefix" on every
+// file in a large ConfigMap) collapse into one line plus a repeat count.
+func setupLogger(level, format string) *slog.Logger {
+	var lvl slog.Level
+	switch strings.ToLower(level) {
+	case "debug":
+		lvl = slog.LevelDebug
+	case "warn":
+		lvl = slog.LevelWarn
+	case "error":
+		lvl = slog.LevelError
+	default:
+		lvl = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	}
+	return slog.New(newDedupingHandler(handler))
+}
+
+// dedupingHandler collapses a run of consecutive, identical log records
+// (same level, message and attributes) into the first occurrence plus a
+// single "repeated N more times" summary once a different record arrives.
+// This is a rebuilt equivalent of the old util/logging Deduper.
+type dedupingHandler struct {
+	next slog.Handler
+
+	mu      sync.Mutex
+	lastKey string
+	last    *slog.Record
+	repeats int
+}
+
+func newDedupingHandler(next slog.Handler) *dedupingHandler {
+	return &dedupingHandler{next: next}
+}
+
+func (h *dedupingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *dedupingHandler) Handle(ctx context.Context, record slog.Record) error {
+	key := recordKey(record)
+
+	h.mu.Lock()
+	if h.last != nil && key == h.lastKey {
+		h.repeats++
+		h.mu.Unlock()
+		return nil
+	}
+	toFlush, flushCount := h.last, h.repeats
+	cloned := record.Clone()
+	h.last = &cloned
+	h.lastKey = key
+	h.repeats = 0
+	h.mu.Unlock()
+
+	if toFlush != nil && flushCount > 0 {
+		summary := toFlush.Clone()
+		summary.Message = fmt.Sprintf("%s (repeated %d more times)", toFlush.Message, flushCount)
+		if err := h.next.Handle(ctx, summary); err != nil {
+			return err
+		}
+	}
+
+	return h.next.Handle(ctx, record)
+}
+
+func (h *dedupingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return newDedupingHandler(h.next.WithAttrs(attrs))
+}
+
+func (h *dedupingHandler) WithGroup(name string) slog.Handler {
+	return newDedupingHandler(h.next.WithGroup(name))
+}
+
+func recordKey(r slog.Record) string {
+	var b strings.Builder
+	b.WriteString(r.Level.String())
+	b.WriteByte('|')
+	b.WriteString(r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		b.WriteByte('|')
+		b.WriteString(a.Key)
+		b.WriteByte('=')
+		fmt.Fprintf(&b, "%v", a.Value.Any())
+		return true
+	})
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	debouncedEventsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "debounced_events_total",
+		Help:      "Total fsnotify events coalesced into a reload by -reload-debounce",
+	})
+	webhookRetryBackoffSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "webhook_retry_backoff_seconds",
+		Help:      "Backoff duration slept between webhook retry attempts",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+	})
+)
+
+func init() {
+	prometheus.MustRegister(debouncedEventsTotal)
+	prometheus.MustRegister(webhookRetryBackoffSeconds)
+}
+
+// debouncer coalesces repeated triggers for the same key, occurring within
+// delay of one another, into a single call to fn once the window goes
+// quiet - so a burst of fsnotify events from one ConfigMap update walks and
+// fires webhooks exactly once instead of once per event.
+type debouncer struct {
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+func newDebouncer() *debouncer {
+	return &debouncer{timers: make(map[string]*time.Timer)}
+}
+
+func (d *debouncer) trigger(key string, delay time.Duration, fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.timers[key]; ok {
+		debouncedEventsTotal.Inc()
+		t.Reset(delay)
+		return
+	}
+
+	d.timers[key] = time.AfterFunc(delay, func() {
+		d.mu.Lock()
+		delete(d.timers, key)
+		d.mu.Unlock()
+		fn()
+	})
+}
+
+// backoffWithJitter returns the delay to sleep before retry attempt n
+// (0-based), growing exponentially from initial up to max and jittered down
+// by up to 50% (i.e. a value in [0.5, 1.0] of the un-jittered backoff) so
+// that many pods retrying the same webhook don't retry in lockstep.
+func backoffWithJitter(attempt int, initial, max time.Duration) time.Duration {
+	backoff := initial
+	for i := 0; i < attempt; i++ {
+		backoff *= 2
+		if backoff <= 0 || backoff > max {
+			backoff = max
+			break
+		}
+	}
+	if backoff <= 0 {
+		backoff = initial
+	}
+
+	half := backoff / 2
+	jittered := half + time.Duration(rand.Int63n(int64(half)+1))
+	webhookRetryBackoffSeconds.Observe(jittered.Seconds())
+	return jittered
+}
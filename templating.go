@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	templateEngine = flag.String("template-engine", "replace", `the engine used to render matched files: "replace" does a literal substitution of env-var keys for their values (default, backward compatible); "go" parses the file as a Go text/template with env/envOr/file/toYaml/indent/sha256sum helpers`)
+	templateStrict = flag.Bool("template-strict", false, "with -template-engine=go, fail the reload instead of rendering an empty string when a referenced env var is not set")
+)
+
+// renderFile renders read according to *templateEngine, substituting the
+// env vars in envMap either literally or via a Go template.
+func renderFile(read []byte, envMap map[string]string) ([]byte, error) {
+	if *templateEngine != "go" {
+		for key, value := range envMap {
+			read = bytes.Replace(read, []byte(key), []byte(value), -1)
+		}
+		return read, nil
+	}
+
+	missingKey := "zero"
+	if *templateStrict {
+		missingKey = "error"
+	}
+	tmpl, err := template.New("configmap-reload").Funcs(templateFuncMap()).Option("missingkey=" + missingKey).Parse(string(read))
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, envMap); err != nil {
+		return nil, fmt.Errorf("executing template: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// templateFuncMap is the Helm/consul-template-style function map available
+// to -template-engine=go files.
+func templateFuncMap() template.FuncMap {
+	return template.FuncMap{
+		"env":       templateEnv,
+		"envOr":     templateEnvOr,
+		"file":      templateFile,
+		"toYaml":    templateToYaml,
+		"indent":    templateIndent,
+		"sha256sum": templateSha256sum,
+	}
+}
+
+func templateEnv(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok && *templateStrict {
+		return "", fmt.Errorf("env variable %q is not set", name)
+	}
+	return value, nil
+}
+
+func templateEnvOr(name, def string) string {
+	if value, ok := os.LookupEnv(name); ok {
+		return value
+	}
+	return def
+}
+
+func templateFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %v", path, err)
+	}
+	return string(data), nil
+}
+
+func templateToYaml(v interface{}) (string, error) {
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("marshaling to yaml: %v", err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func templateIndent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = pad + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func templateSha256sum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}